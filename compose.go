@@ -0,0 +1,279 @@
+package testcontainer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/testcontainers/testcontainer-go/wait"
+)
+
+// TestcontainerLabelComposeStack marks a container as belonging to a
+// ComposeStack, with the value being the service name as declared in the
+// compose file.
+const TestcontainerLabelComposeStack = TestcontainerLabel + ".composeService"
+
+// composeContainerProvider is the subset of DockerProvider needed to create
+// containers for a stack.
+type composeContainerProvider interface {
+	CreateContainer(ctx context.Context, req ContainerRequest) (Container, error)
+}
+
+// composeFile mirrors the handful of docker-compose fields we translate into
+// ContainerRequests. Unknown fields are ignored.
+//
+// DependsOn only covers the list-of-strings shape; compose v2's map-form
+// "depends_on" with condition objects fails yaml.Unmarshal.
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image       string             `yaml:"image"`
+	Command     composeCommand     `yaml:"command"`
+	Environment composeEnvironment `yaml:"environment"`
+	Ports       []string           `yaml:"ports"`
+	DependsOn   []string           `yaml:"depends_on"`
+}
+
+// composeEnvironment accepts docker-compose's two "environment" shapes: a
+// mapping of KEY: value, or a list of "KEY=value" strings.
+type composeEnvironment map[string]string
+
+func (e *composeEnvironment) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var asMap map[string]string
+	if err := unmarshal(&asMap); err == nil {
+		*e = asMap
+		return nil
+	}
+
+	var asList []string
+	if err := unmarshal(&asList); err != nil {
+		return err
+	}
+
+	env := make(map[string]string, len(asList))
+	for _, entry := range asList {
+		key, value := entry, ""
+		if idx := strings.IndexByte(entry, '='); idx >= 0 {
+			key, value = entry[:idx], entry[idx+1:]
+		}
+		env[key] = value
+	}
+	*e = env
+	return nil
+}
+
+// composeCommand accepts docker-compose's two "command" shapes: a single
+// shell string, or an exec-form list of arguments, which are joined with
+// spaces into the form ContainerRequest.Cmd expects.
+type composeCommand string
+
+func (c *composeCommand) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var asString string
+	if err := unmarshal(&asString); err == nil {
+		*c = composeCommand(asString)
+		return nil
+	}
+
+	var asList []string
+	if err := unmarshal(&asList); err != nil {
+		return err
+	}
+	*c = composeCommand(strings.Join(asList, " "))
+	return nil
+}
+
+// ComposeStackProvider creates Stacks from a docker-compose v2/v3 YAML
+// definition, using the Docker SDK directly rather than shelling out to
+// docker-compose.
+type ComposeStackProvider struct {
+	Provider composeContainerProvider
+}
+
+// NewComposeStackProvider builds a ComposeStackProvider backed by the given
+// DockerProvider.
+func NewComposeStackProvider(provider *DockerProvider) *ComposeStackProvider {
+	return &ComposeStackProvider{Provider: provider}
+}
+
+// CreateStack parses a docker-compose YAML document and prepares the
+// containers for each declared service, without starting them.
+func (p *ComposeStackProvider) CreateStack(spec io.Reader) (Stack, error) {
+	raw, err := ioutil.ReadAll(spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading compose spec failed")
+	}
+
+	var file composeFile
+	if err := yaml.Unmarshal(raw, &file); err != nil {
+		return nil, errors.Wrap(err, "parsing compose spec failed")
+	}
+
+	order, err := composeStartOrder(file.Services)
+	if err != nil {
+		return nil, err
+	}
+
+	stack := &ComposeStack{
+		provider:   p.Provider,
+		sessionID:  sessionID(),
+		order:      order,
+		containers: make(map[string]Container, len(file.Services)),
+	}
+
+	for name, svc := range file.Services {
+		req := composeServiceToRequest(name, svc, stack.sessionID)
+
+		c, err := p.Provider.CreateContainer(context.Background(), req)
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating container for service %q failed", name)
+		}
+		stack.containers[name] = c
+	}
+
+	return stack, nil
+}
+
+// composeServiceToRequest translates a single compose service into a
+// ContainerRequest, tagging it with the stack's session label so the Reaper
+// can clean it up, and with the service label naming its compose service.
+func composeServiceToRequest(name string, svc composeService, sessionID string) ContainerRequest {
+	req := ContainerRequest{
+		Image: svc.Image,
+		Env:   map[string]string(svc.Environment),
+		Cmd:   string(svc.Command),
+		Labels: map[string]string{
+			TestcontainerLabelSessionID:    sessionID,
+			TestcontainerLabelComposeStack: name,
+		},
+	}
+
+	for _, port := range svc.Ports {
+		req.ExportedPort = append(req.ExportedPort, composePortSpec(port))
+	}
+
+	return req
+}
+
+// composePortSpec rewrites a compose "ports" entry into the host:container
+// form CreateContainer expects. Compose assigns a random host port when none
+// is given, which we mirror by binding to host port 0 and letting Docker pick
+// one; the real port is then discovered by inspecting the container.
+func composePortSpec(port string) string {
+	if containsHostPort(port) {
+		return port
+	}
+	return "0:" + port
+}
+
+func containsHostPort(port string) bool {
+	for _, r := range port {
+		if r == ':' {
+			return true
+		}
+	}
+	return false
+}
+
+// composeStartOrder topologically sorts service names by depends_on so
+// ComposeStack.Start can bring dependencies up first.
+func composeStartOrder(services map[string]composeService) ([]string, error) {
+	order := make([]string, 0, len(services))
+	visited := make(map[string]bool, len(services))
+	visiting := make(map[string]bool, len(services))
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		if visited[name] {
+			return nil
+		}
+		if visiting[name] {
+			return fmt.Errorf("circular depends_on involving service %q", name)
+		}
+		svc, ok := services[name]
+		if !ok {
+			return fmt.Errorf("depends_on references unknown service %q", name)
+		}
+
+		visiting[name] = true
+		for _, dep := range svc.DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+
+		visited[name] = true
+		order = append(order, name)
+		return nil
+	}
+
+	for name := range services {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// ComposeStack is a Stack started from a docker-compose definition.
+type ComposeStack struct {
+	provider   composeContainerProvider
+	sessionID  string
+	order      []string
+	containers map[string]Container
+}
+
+// Start brings up every service's container in depends_on order. Mapped
+// ports are read from the daemon on demand by GetMappedPort/GetHostEndpoint,
+// so no separate re-discovery step is needed here.
+func (s *ComposeStack) Start(ctx context.Context) error {
+	for _, name := range s.order {
+		c, ok := s.containers[name]
+		if !ok {
+			continue
+		}
+		if err := c.Start(ctx); err != nil {
+			return errors.Wrapf(err, "starting service %q failed", name)
+		}
+	}
+	return nil
+}
+
+// Terminate stops and removes every container belonging to the stack.
+func (s *ComposeStack) Terminate(ctx context.Context) error {
+	var firstErr error
+	for name, c := range s.containers {
+		if err := c.Terminate(ctx); err != nil && firstErr == nil {
+			firstErr = errors.Wrapf(err, "terminating service %q failed", name)
+		}
+	}
+	return firstErr
+}
+
+// ServiceContainer returns the Container started for the given compose
+// service name.
+func (s *ComposeStack) ServiceContainer(name string) (Container, error) {
+	c, ok := s.containers[name]
+	if !ok {
+		return nil, fmt.Errorf("service %q not found in stack", name)
+	}
+	return c, nil
+}
+
+// WaitForService runs a wait strategy against the named service's
+// (re-discovered) endpoint.
+func (s *ComposeStack) WaitForService(ctx context.Context, name string, strategy wait.WaitStrategy) error {
+	c, err := s.ServiceContainer(name)
+	if err != nil {
+		return err
+	}
+	return strategy.WaitUntilReady(ctx, c)
+}
@@ -0,0 +1,118 @@
+package testcontainer
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestComposeStartOrder(t *testing.T) {
+	services := map[string]composeService{
+		"web": {DependsOn: []string{"api"}},
+		"api": {DependsOn: []string{"db"}},
+		"db":  {},
+	}
+
+	order, err := composeStartOrder(services)
+	if err != nil {
+		t.Fatalf("composeStartOrder returned error: %s", err)
+	}
+
+	pos := make(map[string]int, len(order))
+	for i, name := range order {
+		pos[name] = i
+	}
+	if pos["db"] > pos["api"] {
+		t.Errorf("expected db before api, got order %v", order)
+	}
+	if pos["api"] > pos["web"] {
+		t.Errorf("expected api before web, got order %v", order)
+	}
+
+	got := append([]string{}, order...)
+	sort.Strings(got)
+	want := []string{"api", "db", "web"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("order contains %v, want every service once: %v", got, want)
+	}
+}
+
+func TestComposeStartOrderCycle(t *testing.T) {
+	services := map[string]composeService{
+		"a": {DependsOn: []string{"b"}},
+		"b": {DependsOn: []string{"a"}},
+	}
+
+	if _, err := composeStartOrder(services); err == nil {
+		t.Fatal("expected an error for a circular depends_on, got nil")
+	}
+}
+
+func TestComposeStartOrderUnknownDependency(t *testing.T) {
+	services := map[string]composeService{
+		"a": {DependsOn: []string{"missing"}},
+	}
+
+	if _, err := composeStartOrder(services); err == nil {
+		t.Fatal("expected an error for a depends_on referencing an unknown service, got nil")
+	}
+}
+
+func TestComposePortSpec(t *testing.T) {
+	cases := map[string]string{
+		"8080":              "0:8080",
+		"8080/tcp":          "0:8080/tcp",
+		"80:8080":           "80:8080",
+		"127.0.0.1:80:8080": "127.0.0.1:80:8080",
+	}
+
+	for in, want := range cases {
+		if got := composePortSpec(in); got != want {
+			t.Errorf("composePortSpec(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestComposeEnvironmentMapForm(t *testing.T) {
+	var env composeEnvironment
+	if err := yaml.Unmarshal([]byte("FOO: bar\nBAZ: qux"), &env); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	want := composeEnvironment{"FOO": "bar", "BAZ": "qux"}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("got %v, want %v", env, want)
+	}
+}
+
+func TestComposeEnvironmentListForm(t *testing.T) {
+	var env composeEnvironment
+	if err := yaml.Unmarshal([]byte("- FOO=bar\n- BAZ=qux\n- NOVALUE"), &env); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	want := composeEnvironment{"FOO": "bar", "BAZ": "qux", "NOVALUE": ""}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("got %v, want %v", env, want)
+	}
+}
+
+func TestComposeCommandStringForm(t *testing.T) {
+	var cmd composeCommand
+	if err := yaml.Unmarshal([]byte("sh -c 'echo hi'"), &cmd); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if want := composeCommand("sh -c 'echo hi'"); cmd != want {
+		t.Errorf("got %q, want %q", cmd, want)
+	}
+}
+
+func TestComposeCommandListForm(t *testing.T) {
+	var cmd composeCommand
+	if err := yaml.Unmarshal([]byte("- sh\n- -c\n- echo hi"), &cmd); err != nil {
+		t.Fatalf("unmarshal failed: %s", err)
+	}
+	if want := composeCommand("sh -c echo hi"); cmd != want {
+		t.Errorf("got %q, want %q", cmd, want)
+	}
+}
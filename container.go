@@ -21,20 +21,70 @@ type Container interface {
 	GetIPAddress(context.Context) (string, error)            // IP address where the container port is exposed
 	GetPorts(context.Context) (nat.PortSet, error)           // all exposed ports
 	GetMappedPort(context.Context, uint16) (string, error)   // the externally mapped port for a container port
+	Host(context.Context) (string, error)                    // host on which the mapped ports are reachable
 	Start(context.Context) error                             // start the container
 	Terminate(context.Context) error                         // terminate the container
+	Logs(context.Context) (io.ReadCloser, error)             // stream the container's combined stdout/stderr
+	FollowOutput(LogConsumer)                                // register a consumer to receive logs from StartLogProducer
+	StartLogProducer(context.Context) error                  // start streaming logs to registered consumers
+	StopLogProducer() error                                  // stop streaming logs
+}
+
+// LogType identifies which container stream a Log came from.
+type LogType string
+
+const (
+	StdoutLog LogType = "STDOUT"
+	StderrLog LogType = "STDERR"
+)
+
+// Log is a chunk of container output handed to a LogConsumer, already
+// demultiplexed from Docker's combined stdout/stderr stream.
+type Log struct {
+	LogType LogType
+	Content []byte
+}
+
+// LogConsumer receives Logs from a container that had StartLogProducer
+// called on it, via FollowOutput. A wait strategy that keys on log output
+// (e.g. waiting for a line to match a regex) can be built on top of this.
+type LogConsumer interface {
+	Accept(Log)
+}
+
+// FromDockerfile represents the parameters needed to build an image from a
+// Dockerfile rather than pulling one, as an alternative to ContainerRequest.Image.
+type FromDockerfile struct {
+	Context       string             // the path to the build context
+	Dockerfile    string             // the path to the Dockerfile, relative to the context. Defaults to "Dockerfile"
+	BuildArgs     map[string]*string // build args passed through to the build, in the same format as dockerclient's ImageBuildOptions
+	PrintBuildLog bool               // whether to print the build log to stdout
 }
 
 // ContainerRequest represents the parameters used to get a running container
 type ContainerRequest struct {
-	Image        string
-	Env          map[string]string
-	ExportedPort []string
-	Cmd          string
-	Labels       map[string]string
-	RegistryCred string
-	WaitingFor   wait.WaitStrategy
-	Mounts       []mount.Mount
+	Image           string
+	Env             map[string]string
+	ExportedPort    []string
+	Cmd             string
+	Labels          map[string]string
+	RegistryCred    string
+	WaitingFor      wait.WaitStrategy
+	Mounts          []mount.Mount
+	FromDockerfile  FromDockerfile
+	AlwaysPullImage bool   // always pull the image, even if it's already present locally
+	Platform        string // e.g. "linux/amd64", passed to the image pull
+
+	// skipReaper marks a request as the Ryuk sidecar's own, so CreateContainer
+	// doesn't recurse back into NewReaper while the reaper for this session is
+	// still being created.
+	skipReaper bool
+}
+
+// ShouldBuildImage returns true if the request describes a build context to
+// produce the image from, rather than an image to pull.
+func (c *ContainerRequest) ShouldBuildImage() bool {
+	return c.FromDockerfile.Context != ""
 }
 
 // StackProvider allows the creation of a stack of containers on an arbitrary system
@@ -42,6 +92,18 @@ type StackProvider interface {
 	CreateStack(spec io.Reader) (Stack, error)
 }
 
+// Stack represents a group of containers started from a docker-compose style
+// definition, tied together under a shared session so they can be cleaned up
+// as a unit.
 type Stack interface {
-	// tbd
+	// Start brings up all services in the stack, respecting depends_on order.
+	Start(ctx context.Context) error
+	// Terminate tears down every container belonging to the stack.
+	Terminate(ctx context.Context) error
+	// ServiceContainer returns the running Container for the given compose
+	// service name.
+	ServiceContainer(name string) (Container, error)
+	// WaitForService blocks until the given service satisfies the wait
+	// strategy.
+	WaitForService(ctx context.Context, name string, strategy wait.WaitStrategy) error
 }
@@ -2,31 +2,50 @@ package testcontainer
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net/url"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
-	uuid "github.com/satori/go.uuid"
 	"github.com/testcontainers/testcontainer-go/wait"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/docker/go-connections/nat"
+
+	"github.com/testcontainers/testcontainer-go/errdefs"
 )
 
+// daemonHostEnvVar overrides host resolution for setups (Docker Desktop, an
+// SSH tunnel, a remote DOCKER_HOST) where the daemon's own idea of its host
+// isn't reachable from the test process.
+const daemonHostEnvVar = "TC_HOST"
+
 // DockerContainer represents a container started using Docker
 type DockerContainer struct {
 	// Container ID from Docker
 	ID         string
 	WaitingFor wait.WaitStrategy
 
-	sessionID uuid.UUID
+	sessionID string
 	// Cache to retrieve container infromation without re-fetching them from dockerd
 	raw      *types.ContainerJSON
 	provider *DockerProvider
+
+	logMu           sync.Mutex
+	logConsumers    []LogConsumer
+	logProducerStop chan struct{}
 }
 
 // LivenessCheckPorts (deprecated) returns the exposed ports for the container.
@@ -51,10 +70,13 @@ func (c *DockerContainer) GetMappedPort(ctx context.Context, port uint16) (strin
 
 	for k, p := range inspect.NetworkSettings.Ports {
 		if k.Port() == strconv.Itoa(int(port)) {
+			if len(p) == 0 {
+				return "", errdefs.NotFound(fmt.Errorf("port %d not found", port))
+			}
 			return p[0].HostPort, nil
 		}
 	}
-	return "0", nil
+	return "", errdefs.NotFound(fmt.Errorf("port %d not found", port))
 }
 
 // Start will start an already created container
@@ -86,12 +108,21 @@ func (c *DockerContainer) inspectContainer(ctx context.Context) (*types.Containe
 	}
 	inspect, err := c.provider.client.ContainerInspect(ctx, c.ID)
 	if err != nil {
-		return nil, err
+		if client.IsErrNotFound(err) {
+			return nil, errdefs.NotFound(err)
+		}
+		return nil, errdefs.System(err)
 	}
 	c.raw = &inspect
 	return c.raw, nil
 }
 
+// Host returns the host on which this container's mapped ports can be
+// reached, resolved via DockerProvider.daemonHost.
+func (c *DockerContainer) Host(ctx context.Context) (string, error) {
+	return c.provider.daemonHost(ctx)
+}
+
 // GetIPAddress returns the ip address for the running container.
 func (c *DockerContainer) GetIPAddress(ctx context.Context) (string, error) {
 	inspect, err := c.inspectContainer(ctx)
@@ -110,23 +141,28 @@ func (c *DockerContainer) GetHostEndpoint(ctx context.Context, port string) (str
 
 	portSet, _, err := nat.ParsePortSpecs([]string{port})
 	if err != nil {
-		return "", err
+		return "", errdefs.InvalidParameter(err)
 	}
 
 	for p := range portSet {
 		ports, ok := inspect.NetworkSettings.Ports[p]
 		if !ok {
-			return "", fmt.Errorf("port %s not found", port)
+			return "", errdefs.NotFound(fmt.Errorf("port %s not found", port))
 		}
 		if len(ports) == 0 {
-			return "", fmt.Errorf("port %s not found", port)
+			return "", errdefs.NotFound(fmt.Errorf("port %s not found", port))
 		}
 
-		return fmt.Sprintf("%s:%s", ports[0].HostIP, ports[0].HostPort), nil
+		host, err := c.provider.daemonHost(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("%s:%s", host, ports[0].HostPort), nil
 
 	}
 
-	return "", fmt.Errorf("port %s not found", port)
+	return "", errdefs.NotFound(fmt.Errorf("port %s not found", port))
 }
 
 type DockerProvider struct {
@@ -137,7 +173,7 @@ type DockerProvider struct {
 func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerRequest) (Container, error) {
 	exposedPortSet, exposedPortMap, err := nat.ParsePortSpecs(req.ExportedPort)
 	if err != nil {
-		return nil, err
+		return nil, errdefs.InvalidParameter(err)
 	}
 
 	env := []string{}
@@ -145,46 +181,41 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 		env = append(env, envKey+"="+envVar)
 	}
 
-	sessionID := uuid.NewV4()
-	r, err := NewReaper(ctx, sessionID.String(), p)
-	if err != nil {
-		return nil, errors.Wrap(err, "creating reaper failed")
+	sessID := sessionID()
+
+	labels := map[string]string{}
+	if !req.skipReaper {
+		r, err := NewReaper(ctx, sessID, p)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating reaper failed")
+		}
+		labels = r.GetLabels()
+	}
+	for k, v := range req.Labels {
+		labels[k] = v
+	}
+
+	if req.ShouldBuildImage() {
+		tag, err := p.buildImage(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		req.Image = tag
+	} else if err := p.pullImageIfNeeded(ctx, req); err != nil {
+		return nil, err
 	}
 
 	dockerInput := &container.Config{
 		Image:        req.Image,
 		Env:          env,
 		ExposedPorts: exposedPortSet,
-		Labels:       r.GetLabels(),
+		Labels:       labels,
 	}
 
 	if req.Cmd != "" {
 		dockerInput.Cmd = strings.Split(req.Cmd, " ")
 	}
 
-	_, _, err = p.client.ImageInspectWithRaw(ctx, req.Image)
-	if err != nil {
-		if client.IsErrNotFound(err) {
-			pullOpt := types.ImagePullOptions{}
-			if req.RegistryCred != "" {
-				pullOpt.RegistryAuth = req.RegistryCred
-			}
-			pull, err := p.client.ImagePull(ctx, req.Image, pullOpt)
-			if err != nil {
-				return nil, err
-			}
-			defer pull.Close()
-
-			// download of docker image finishes at EOF of the pull request
-			_, err = ioutil.ReadAll(pull)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			return nil, err
-		}
-	}
-
 	hostConfig := &container.HostConfig{
 		PortBindings: exposedPortMap,
 		Mounts:       req.Mounts,
@@ -192,19 +223,184 @@ func (p *DockerProvider) CreateContainer(ctx context.Context, req ContainerReque
 
 	resp, err := p.client.ContainerCreate(ctx, dockerInput, hostConfig, nil, "")
 	if err != nil {
-		return nil, err
+		if client.IsErrNotFound(err) {
+			return nil, errdefs.NotFound(err)
+		}
+		return nil, errdefs.System(err)
 	}
 
 	c := &DockerContainer{
 		ID:         resp.ID,
 		WaitingFor: req.WaitingFor,
-		sessionID:  sessionID,
+		sessionID:  sessID,
 		provider:   p,
 	}
 
 	return c, nil
 }
 
+// pullImageIfNeeded pulls req.Image unless it's already present locally and
+// AlwaysPullImage wasn't requested.
+func (p *DockerProvider) pullImageIfNeeded(ctx context.Context, req ContainerRequest) error {
+	if !req.AlwaysPullImage {
+		_, _, err := p.client.ImageInspectWithRaw(ctx, req.Image)
+		if err == nil {
+			return nil
+		}
+		if !client.IsErrNotFound(err) {
+			return errdefs.System(err)
+		}
+	}
+
+	pullOpt := types.ImagePullOptions{Platform: req.Platform}
+	if req.RegistryCred != "" {
+		pullOpt.RegistryAuth = req.RegistryCred
+	}
+	pull, err := p.client.ImagePull(ctx, req.Image, pullOpt)
+	if err != nil {
+		return errdefs.System(err)
+	}
+	defer pull.Close()
+
+	// download of docker image finishes at EOF of the pull request
+	_, err = ioutil.ReadAll(pull)
+	if err != nil {
+		return errdefs.System(err)
+	}
+
+	return nil
+}
+
+// buildCounter disambiguates the tags buildImage assigns so that multiple
+// build contexts built by the same process never collide on one tag.
+var buildCounter int64
+
+// buildImage builds req.FromDockerfile's build context and returns the
+// resulting image's ID, or its tag if the build output didn't carry one
+// (classic, non-BuildKit builds emit it in an aux message; BuildKit's aux
+// payload uses a different schema, so it's left empty), for use in place of
+// req.Image.
+func (p *DockerProvider) buildImage(ctx context.Context, req *ContainerRequest) (string, error) {
+	buildContext, err := archive.TarWithOptions(req.FromDockerfile.Context, &archive.TarOptions{})
+	if err != nil {
+		return "", errdefs.System(err)
+	}
+	defer buildContext.Close()
+
+	dockerfile := req.FromDockerfile.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+
+	tag := fmt.Sprintf("testcontainer-build-%s-%d", sessionID(), atomic.AddInt64(&buildCounter, 1))
+
+	resp, err := p.client.ImageBuild(ctx, buildContext, types.ImageBuildOptions{
+		Dockerfile: dockerfile,
+		BuildArgs:  req.FromDockerfile.BuildArgs,
+		Tags:       []string{tag},
+	})
+	if err != nil {
+		return "", errdefs.System(err)
+	}
+	defer resp.Body.Close()
+
+	out := io.Writer(ioutil.Discard)
+	if req.FromDockerfile.PrintBuildLog {
+		out = os.Stdout
+	}
+
+	var imageID string
+	auxCallback := func(msg jsonmessage.JSONMessage) {
+		if msg.Aux == nil {
+			return
+		}
+		var result struct {
+			ID string `json:"ID"`
+		}
+		if err := json.Unmarshal(*msg.Aux, &result); err == nil && result.ID != "" {
+			imageID = result.ID
+		}
+	}
+
+	if err := jsonmessage.DisplayJSONMessagesStream(resp.Body, out, 0, false, auxCallback); err != nil {
+		return "", errdefs.System(err)
+	}
+	if imageID != "" {
+		return imageID, nil
+	}
+
+	return tag, nil
+}
+
+// LookupContainer returns a container already running under the given
+// labels, if one exists. It's used by the Reaper to find an existing Ryuk
+// sidecar before starting a new one.
+func (p *DockerProvider) LookupContainer(ctx context.Context, labels map[string]string) (Container, error) {
+	args := filters.NewArgs()
+	for k, v := range labels {
+		args.Add("label", fmt.Sprintf("%s=%s", k, v))
+	}
+
+	containers, err := p.client.ContainerList(ctx, types.ContainerListOptions{Filters: args})
+	if err != nil {
+		if client.IsErrConnectionFailed(err) {
+			return nil, errdefs.Unavailable(err)
+		}
+		return nil, errdefs.System(err)
+	}
+	if len(containers) == 0 {
+		return nil, errdefs.NotFound(fmt.Errorf("no container found for labels %v", labels))
+	}
+
+	return &DockerContainer{ID: containers[0].ID, provider: p}, nil
+}
+
+// daemonHost resolves the host on which mapped container ports are actually
+// reachable. Inspect's HostIP is usually 0.0.0.0, and is outright wrong when
+// the daemon isn't local (Docker Desktop, an SSH tunnel, a remote
+// DOCKER_HOST). TC_HOST always wins; otherwise the daemon endpoint is
+// parsed: unix and npipe sockets give "localhost", tcp gives the endpoint's
+// host. Only an endpoint with any other scheme falls back to the bridge
+// network's gateway; Docker-in-Docker is not detected, and no resolved host
+// is checked for reachability.
+func (p *DockerProvider) daemonHost(ctx context.Context) (string, error) {
+	if host := os.Getenv(daemonHostEnvVar); host != "" {
+		return host, nil
+	}
+
+	daemonURL, err := url.Parse(p.client.DaemonHost())
+	if err != nil {
+		return "", errdefs.System(err)
+	}
+
+	switch daemonURL.Scheme {
+	case "unix", "npipe":
+		return "localhost", nil
+	case "tcp":
+		return daemonURL.Hostname(), nil
+	}
+
+	return p.bridgeGatewayHost(ctx)
+}
+
+// bridgeGatewayHost inspects the default bridge network from inside the
+// daemon and returns its gateway address, which is reachable from a
+// container running alongside the test process (Docker-in-Docker).
+func (p *DockerProvider) bridgeGatewayHost(ctx context.Context) (string, error) {
+	bridge, err := p.client.NetworkInspect(ctx, "bridge", types.NetworkInspectOptions{})
+	if err != nil {
+		return "", errdefs.System(err)
+	}
+
+	for _, cfg := range bridge.IPAM.Config {
+		if cfg.Gateway != "" {
+			return cfg.Gateway, nil
+		}
+	}
+
+	return "", errdefs.NotFound(errors.New("no gateway found on the bridge network"))
+}
+
 // RunContainer takes a RequestContainer as input and it runs a container via the docker sdk
 func (p *DockerProvider) RunContainer(ctx context.Context, req ContainerRequest) (Container, error) {
 	c, err := p.CreateContainer(ctx, req)
@@ -0,0 +1,103 @@
+// Package errdefs defines the error interfaces that container lifecycle
+// failures are classified into, so callers can branch on failure mode
+// instead of matching error strings.
+package errdefs
+
+// ErrNotFound signals that the requested container, port or resource does
+// not exist.
+type ErrNotFound interface {
+	error
+	NotFound()
+}
+
+// ErrInvalidParameter signals that a caller-supplied value (a port spec, an
+// image reference, ...) was malformed.
+type ErrInvalidParameter interface {
+	error
+	InvalidParameter()
+}
+
+// ErrConflict signals that the operation can't proceed because something it
+// would create already exists.
+type ErrConflict interface {
+	error
+	Conflict()
+}
+
+// ErrUnavailable signals that the Docker daemon could not be reached.
+type ErrUnavailable interface {
+	error
+	Unavailable()
+}
+
+// ErrSystem signals a failure while talking to the Docker API (inspect,
+// create, ...) that doesn't fit any of the other categories.
+type ErrSystem interface {
+	error
+	System()
+}
+
+type notFoundErr struct{ error }
+
+func (notFoundErr) NotFound() {}
+
+// NotFound wraps err so that errdefs.IsNotFound(err) is true. Returns nil if
+// err is nil.
+func NotFound(err error) error {
+	if err == nil {
+		return nil
+	}
+	return notFoundErr{err}
+}
+
+type invalidParameterErr struct{ error }
+
+func (invalidParameterErr) InvalidParameter() {}
+
+// InvalidParameter wraps err so that errdefs.IsInvalidParameter(err) is
+// true. Returns nil if err is nil.
+func InvalidParameter(err error) error {
+	if err == nil {
+		return nil
+	}
+	return invalidParameterErr{err}
+}
+
+type conflictErr struct{ error }
+
+func (conflictErr) Conflict() {}
+
+// Conflict wraps err so that errdefs.IsConflict(err) is true. Returns nil if
+// err is nil.
+func Conflict(err error) error {
+	if err == nil {
+		return nil
+	}
+	return conflictErr{err}
+}
+
+type unavailableErr struct{ error }
+
+func (unavailableErr) Unavailable() {}
+
+// Unavailable wraps err so that errdefs.IsUnavailable(err) is true. Returns
+// nil if err is nil.
+func Unavailable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return unavailableErr{err}
+}
+
+type systemErr struct{ error }
+
+func (systemErr) System() {}
+
+// System wraps err so that errdefs.IsSystem(err) is true. Returns nil if err
+// is nil.
+func System(err error) error {
+	if err == nil {
+		return nil
+	}
+	return systemErr{err}
+}
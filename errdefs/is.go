@@ -0,0 +1,52 @@
+package errdefs
+
+// causer matches github.com/pkg/errors' Causer interface without importing
+// it, so IsNotFound et al. keep working on errors wrapped with
+// errors.Wrap/errors.Wrapf.
+type causer interface {
+	Cause() error
+}
+
+// IsNotFound returns true if err, or any error it wraps, satisfies
+// ErrNotFound.
+func IsNotFound(err error) bool {
+	return as(err, func(err error) bool { _, ok := err.(ErrNotFound); return ok })
+}
+
+// IsInvalidParameter returns true if err, or any error it wraps, satisfies
+// ErrInvalidParameter.
+func IsInvalidParameter(err error) bool {
+	return as(err, func(err error) bool { _, ok := err.(ErrInvalidParameter); return ok })
+}
+
+// IsConflict returns true if err, or any error it wraps, satisfies
+// ErrConflict.
+func IsConflict(err error) bool {
+	return as(err, func(err error) bool { _, ok := err.(ErrConflict); return ok })
+}
+
+// IsUnavailable returns true if err, or any error it wraps, satisfies
+// ErrUnavailable.
+func IsUnavailable(err error) bool {
+	return as(err, func(err error) bool { _, ok := err.(ErrUnavailable); return ok })
+}
+
+// IsSystem returns true if err, or any error it wraps, satisfies ErrSystem.
+func IsSystem(err error) bool {
+	return as(err, func(err error) bool { _, ok := err.(ErrSystem); return ok })
+}
+
+// as walks err's Cause() chain, reporting true as soon as match does.
+func as(err error, match func(error) bool) bool {
+	for err != nil {
+		if match(err) {
+			return true
+		}
+		cause, ok := err.(causer)
+		if !ok {
+			return false
+		}
+		err = cause.Cause()
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package errdefs
+
+import (
+	"errors"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+func TestIsNotFoundDirect(t *testing.T) {
+	err := NotFound(errors.New("boom"))
+	if !IsNotFound(err) {
+		t.Error("expected IsNotFound to be true for a directly wrapped error")
+	}
+	if IsConflict(err) {
+		t.Error("expected IsConflict to be false for a NotFound error")
+	}
+}
+
+func TestIsNotFoundThroughCauseChain(t *testing.T) {
+	err := pkgerrors.Wrap(NotFound(errors.New("boom")), "creating reaper failed")
+	if !IsNotFound(err) {
+		t.Error("expected IsNotFound to see through a pkg/errors Wrap")
+	}
+}
+
+func TestIsNotFoundNil(t *testing.T) {
+	if NotFound(nil) != nil {
+		t.Error("expected NotFound(nil) to return nil")
+	}
+	if IsNotFound(nil) {
+		t.Error("expected IsNotFound(nil) to be false")
+	}
+}
+
+func TestIsUnavailable(t *testing.T) {
+	err := pkgerrors.Wrap(Unavailable(errors.New("daemon down")), "connecting failed")
+	if !IsUnavailable(err) {
+		t.Error("expected IsUnavailable to see through a pkg/errors Wrap")
+	}
+	if IsSystem(err) {
+		t.Error("expected IsSystem to be false for an Unavailable error")
+	}
+}
@@ -0,0 +1,126 @@
+package testcontainer
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/testcontainers/testcontainer-go/errdefs"
+)
+
+// Logs returns a reader over the container's combined, multiplexed
+// stdout/stderr up to now. Use stdcopy.StdCopy to demultiplex it, or
+// StartLogProducer/FollowOutput to have it demultiplexed and delivered to a
+// LogConsumer as it's produced.
+func (c *DockerContainer) Logs(ctx context.Context) (io.ReadCloser, error) {
+	logs, err := c.provider.client.ContainerLogs(ctx, c.ID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+	})
+	if err != nil {
+		return nil, errdefs.System(err)
+	}
+	return logs, nil
+}
+
+// FollowOutput registers a consumer to receive Logs once StartLogProducer is
+// called. It must be called before StartLogProducer.
+func (c *DockerContainer) FollowOutput(consumer LogConsumer) {
+	c.logMu.Lock()
+	defer c.logMu.Unlock()
+	c.logConsumers = append(c.logConsumers, consumer)
+}
+
+// StartLogProducer streams the container's live output to every consumer
+// registered via FollowOutput, demultiplexing stdout from stderr along the
+// way. Call StopLogProducer to stop it.
+func (c *DockerContainer) StartLogProducer(ctx context.Context) error {
+	c.logMu.Lock()
+	defer c.logMu.Unlock()
+
+	if c.logProducerStop != nil {
+		return errdefs.Conflict(fmt.Errorf("log producer for container %s is already running", c.ID))
+	}
+
+	logs, err := c.provider.client.ContainerLogs(ctx, c.ID, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+	})
+	if err != nil {
+		return errdefs.System(err)
+	}
+
+	stop := make(chan struct{})
+	c.logProducerStop = stop
+
+	go func() {
+		defer logs.Close()
+
+		copyDone := make(chan error, 1)
+		go func() {
+			_, err := stdcopy.StdCopy(
+				&logConsumerWriter{container: c, logType: StdoutLog},
+				&logConsumerWriter{container: c, logType: StderrLog},
+				logs,
+			)
+			copyDone <- err
+		}()
+
+		select {
+		case <-stop:
+		case <-copyDone:
+			// The stream ended on its own (e.g. the container exited)
+			// rather than via StopLogProducer, so clear logProducerStop
+			// ourselves; otherwise a later StartLogProducer would see a
+			// stale, already-finished stop channel and report a spurious
+			// conflict.
+			c.logMu.Lock()
+			if c.logProducerStop == stop {
+				c.logProducerStop = nil
+			}
+			c.logMu.Unlock()
+		}
+	}()
+
+	return nil
+}
+
+// StopLogProducer stops a log producer started with StartLogProducer. It's a
+// no-op if none is running.
+func (c *DockerContainer) StopLogProducer() error {
+	c.logMu.Lock()
+	defer c.logMu.Unlock()
+
+	if c.logProducerStop == nil {
+		return nil
+	}
+	close(c.logProducerStop)
+	c.logProducerStop = nil
+	return nil
+}
+
+// logConsumerWriter adapts a container's registered LogConsumers to the
+// io.Writer stdcopy.StdCopy demultiplexes each stream into.
+type logConsumerWriter struct {
+	container *DockerContainer
+	logType   LogType
+}
+
+func (w *logConsumerWriter) Write(p []byte) (int, error) {
+	content := make([]byte, len(p))
+	copy(content, p)
+
+	w.container.logMu.Lock()
+	consumers := make([]LogConsumer, len(w.container.logConsumers))
+	copy(consumers, w.container.logConsumers)
+	w.container.logMu.Unlock()
+
+	for _, consumer := range consumers {
+		consumer.Accept(Log{LogType: w.logType, Content: content})
+	}
+	return len(p), nil
+}
@@ -5,22 +5,59 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/docker/docker/api/types/mount"
 
 	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/testcontainers/testcontainer-go/errdefs"
 )
 
 // TestcontainerLabel is used as a base for docker labels
 const (
 	TestcontainerLabel          = "org.testcontainers.golang"
 	TestcontainerLabelSessionID = TestcontainerLabel + ".sessionId"
+	TestcontainerLabelIsReaper  = TestcontainerLabel + ".ryuk"
 	ReaperDefaultImage          = "quay.io/testcontainers/ryuk:0.2.2"
+
+	// reaperConnectRetries is how many attempts Connect makes at dialing,
+	// and at handshaking with, Ryuk before giving up.
+	reaperConnectRetries = 5
+	// reaperConnectBaseDelay is the initial backoff between retries; it
+	// doubles after every attempt.
+	reaperConnectBaseDelay = 100 * time.Millisecond
+)
+
+var (
+	processSessionID     string
+	processSessionIDOnce sync.Once
+
+	reaperSingleflight singleflight.Group
 )
 
+// sessionID returns a session identifier that is stable for the lifetime of
+// this process, derived once from the PID and the time it was first
+// requested. Reusing one session ID for every container created by the
+// process lets NewReaper converge all of them onto a single Ryuk sidecar
+// instead of spawning one per container.
+func sessionID() string {
+	processSessionIDOnce.Do(func() {
+		processSessionID = fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+	})
+	return processSessionID
+}
+
+// ReaperProvider is the subset of DockerProvider the Reaper needs: the
+// ability to start the Ryuk sidecar, and to find one that is already
+// running.
 type ReaperProvider interface {
 	RunContainer(ctx context.Context, req ContainerRequest) (Container, error)
+	LookupContainer(ctx context.Context, labels map[string]string) (Container, error)
 }
 
 type Reaper struct {
@@ -29,15 +66,39 @@ type Reaper struct {
 	Endpoint  string
 }
 
+// NewReaper returns the Reaper for the given session, starting a Ryuk
+// sidecar if one isn't already running under this session's labels. Calls
+// for the same session ID that race are collapsed via singleflight so only
+// one sidecar is ever started.
 func NewReaper(ctx context.Context, sessionID string, provider ReaperProvider) (*Reaper, error) {
+	v, err, _ := reaperSingleflight.Do(sessionID, func() (interface{}, error) {
+		return newReaper(ctx, sessionID, provider)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Reaper), nil
+}
+
+func newReaper(ctx context.Context, sessionID string, provider ReaperProvider) (*Reaper, error) {
 	r := &Reaper{
 		Provider:  provider,
 		SessionID: sessionID,
 	}
 
+	if existing, err := provider.LookupContainer(ctx, r.reaperLabels()); err == nil {
+		endpoint, err := existing.GetHostEndpoint(ctx, "8080")
+		if err != nil {
+			return nil, err
+		}
+		r.Endpoint = endpoint
+		return r, nil
+	}
+
 	req := ContainerRequest{
-		Image:  ReaperDefaultImage,
-		Labels: r.GetLabels(),
+		Image:      ReaperDefaultImage,
+		Labels:     r.reaperLabels(),
+		skipReaper: true,
 		Mounts: []mount.Mount{
 			{
 				Type:   mount.TypeBind,
@@ -61,11 +122,27 @@ func NewReaper(ctx context.Context, sessionID string, provider ReaperProvider) (
 	return r, nil
 }
 
+// reaperUnreachableError is returned by Connect when Ryuk could not be
+// reached after exhausting all retries.
+type reaperUnreachableError struct {
+	endpoint string
+	attempts int
+	cause    error
+}
+
+func (e *reaperUnreachableError) Error() string {
+	return fmt.Sprintf("reaper unreachable at %s after %d attempts: %s", e.endpoint, e.attempts, e.cause)
+}
+
+func (e *reaperUnreachableError) Cause() error {
+	return e.cause
+}
+
 // Connect runs a goroutine which can be terminated by sending true into the returned channel
 func (r *Reaper) Connect() (chan bool, error) {
-	conn, err := net.Dial("tcp", r.Endpoint)
+	conn, err := dialReaperWithBackoff(r.Endpoint)
 	if err != nil {
-		return nil, errors.Wrap(err, "Connecting to Ryuk on "+r.Endpoint+" failed")
+		return nil, err
 	}
 
 	terminationSignal := make(chan bool)
@@ -78,26 +155,9 @@ func (r *Reaper) Connect() (chan bool, error) {
 			labelFilters = append(labelFilters, fmt.Sprintf("label=%s=%s", l, v))
 		}
 
-		retryLimit := 3
-		for {
-			if retryLimit <= 0 {
-				fmt.Println("Warning: Could not instrument reaper sidecar. Check for zombie containers!")
-				return
-			}
-			retryLimit--
-
-			sock.WriteString(strings.Join(labelFilters, "&"))
-			if err := sock.Flush(); err != nil {
-				continue
-			}
-
-			resp, err := sock.ReadString('\n')
-			if err != nil {
-				continue
-			}
-			if resp == "ACK" {
-				break
-			}
+		if err := handshakeWithBackoff(sock, strings.Join(labelFilters, "&")); err != nil {
+			fmt.Printf("Warning: Could not instrument reaper sidecar, check for zombie containers: %s\n", errdefs.Unavailable(err))
+			return
 		}
 
 		<-terminationSignal
@@ -105,9 +165,77 @@ func (r *Reaper) Connect() (chan bool, error) {
 	return terminationSignal, nil
 }
 
+// dialReaperWithBackoff retries net.Dial with exponential backoff, returning
+// a typed error once reaperConnectRetries attempts have failed.
+func dialReaperWithBackoff(endpoint string) (net.Conn, error) {
+	var lastErr error
+	delay := reaperConnectBaseDelay
+	for attempt := 1; attempt <= reaperConnectRetries; attempt++ {
+		conn, err := net.Dial("tcp", endpoint)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		if attempt < reaperConnectRetries {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return nil, errdefs.Unavailable(errors.Wrap(&reaperUnreachableError{endpoint: endpoint, attempts: reaperConnectRetries, cause: lastErr}, "Connecting to Ryuk failed"))
+}
+
+// handshakeWithBackoff retries the write/ACK handshake with exponential
+// backoff, returning a typed error once reaperConnectRetries attempts have
+// failed.
+func handshakeWithBackoff(sock *bufio.ReadWriter, filters string) error {
+	var lastErr error
+	delay := reaperConnectBaseDelay
+	for attempt := 1; attempt <= reaperConnectRetries; attempt++ {
+		sock.WriteString(filters)
+		if err := sock.Flush(); err != nil {
+			lastErr = err
+			if attempt < reaperConnectRetries {
+				time.Sleep(delay)
+				delay *= 2
+			}
+			continue
+		}
+
+		resp, err := sock.ReadString('\n')
+		if err != nil {
+			lastErr = err
+			if attempt < reaperConnectRetries {
+				time.Sleep(delay)
+				delay *= 2
+			}
+			continue
+		}
+		if strings.TrimSpace(resp) == "ACK" {
+			return nil
+		}
+		lastErr = fmt.Errorf("unexpected response %q", resp)
+		if attempt < reaperConnectRetries {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return &reaperUnreachableError{endpoint: "ryuk", attempts: reaperConnectRetries, cause: lastErr}
+}
+
+// GetLabels returns the session labels applied to every container created
+// under this session, so the running Ryuk sidecar knows to reap them.
 func (r *Reaper) GetLabels() map[string]string {
 	return map[string]string{
 		TestcontainerLabel:          "true",
 		TestcontainerLabelSessionID: r.SessionID,
 	}
 }
+
+// reaperLabels returns the labels identifying the Ryuk sidecar container
+// itself, used to create or look it up. They must not be applied to user
+// containers, or LookupContainer would match one of those instead of Ryuk.
+func (r *Reaper) reaperLabels() map[string]string {
+	labels := r.GetLabels()
+	labels[TestcontainerLabelIsReaper] = "true"
+	return labels
+}